@@ -0,0 +1,156 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package testing
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/terraform/internal/providers"
+)
+
+// Fault describes a single fault to inject into a MockProvider RPC. RPC
+// selects which provider operation it applies to (e.g.
+// "PlanResourceChange"), and TypeName/ResourceID optionally narrow it to a
+// specific resource type and/or resource id; either left empty matches
+// anything.
+//
+// A Fault fires at most Count times before healing on its own; Count of
+// zero means it fires every time it matches.
+type Fault struct {
+	RPC        string
+	TypeName   string
+	ResourceID string
+
+	// Latency, if set, is slept before the RPC proceeds.
+	Latency time.Duration
+
+	// Err, if set, is returned to the caller in place of the RPC's usual
+	// result.
+	Err error
+
+	// Deferred, if set, is returned alongside Err on PlanResourceChange so
+	// tests can exercise Terraform core's deferred-actions handling without
+	// a real provider choosing to defer.
+	Deferred *providers.Deferred
+
+	// RenewAt, if set, is reported on OpenEphemeralResource's and
+	// RenewEphemeralResource's responses so tests can exercise scheduled
+	// renewal of an ephemeral resource without a real provider choosing a
+	// renewal time.
+	RenewAt time.Time
+
+	Count int
+
+	mu    sync.Mutex
+	fired int
+}
+
+func (f *Fault) matches(rpc, typeName, resourceID string) bool {
+	if f.RPC != rpc {
+		return false
+	}
+	if f.TypeName != "" && f.TypeName != typeName {
+		return false
+	}
+	if f.ResourceID != "" && f.ResourceID != resourceID {
+		return false
+	}
+	return true
+}
+
+// tryFire reports whether the fault should fire, consuming one of its Count
+// occurrences if so.
+func (f *Fault) tryFire() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Count > 0 && f.fired >= f.Count {
+		return false
+	}
+	f.fired++
+	return true
+}
+
+// FaultInjector lets tests declaratively inject latency, transient errors,
+// and deferred responses into a MockProvider's RPCs, keyed by resource type
+// and/or id. This exercises Terraform's retry, deferred-actions, and
+// diagnostics-handling paths without needing a bespoke schema per failure
+// mode.
+//
+// A FaultInjector is safe for concurrent use.
+type FaultInjector struct {
+	mu     sync.Mutex
+	faults []*Fault
+}
+
+// NewFaultInjector returns a FaultInjector with no faults configured.
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{}
+}
+
+// Inject registers a fault, returning it so callers can later inspect how
+// many times it has fired.
+func (fi *FaultInjector) Inject(fault *Fault) *Fault {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.faults = append(fi.faults, fault)
+	return fault
+}
+
+// faultResult is what a triggered Fault contributes to an RPC's response:
+// an error to surface as a diagnostic, a deferred response to report
+// instead of completing normally, a renewal time to report for an ephemeral
+// resource, or all zero values if nothing matched.
+type faultResult struct {
+	Err      error
+	Deferred *providers.Deferred
+	RenewAt  time.Time
+}
+
+// trigger applies the first matching, not-yet-healed fault for the given
+// RPC, type name, and resource id (resourceID may be empty if the RPC has
+// no single resource to key on), sleeping for its latency and returning its
+// error and/or deferred response, if any. It returns a zero faultResult if
+// no fault matched.
+func (fi *FaultInjector) trigger(rpc, typeName, resourceID string) faultResult {
+	if fi == nil {
+		return faultResult{}
+	}
+
+	fi.mu.Lock()
+	var match *Fault
+	for _, f := range fi.faults {
+		if f.matches(rpc, typeName, resourceID) && f.tryFire() {
+			match = f
+			break
+		}
+	}
+	fi.mu.Unlock()
+
+	if match == nil {
+		return faultResult{}
+	}
+	if match.Latency > 0 {
+		time.Sleep(match.Latency)
+	}
+	return faultResult{Err: match.Err, Deferred: match.Deferred, RenewAt: match.RenewAt}
+}
+
+// resourceID extracts the "id" attribute from a resource value for use as a
+// FaultInjector match key, returning "" if v has no known id.
+func resourceID(v cty.Value) string {
+	if v.IsNull() || !v.IsKnown() {
+		return ""
+	}
+	if !v.Type().IsObjectType() || !v.Type().HasAttribute("id") {
+		return ""
+	}
+	id := v.GetAttr("id")
+	if id.IsNull() || !id.IsKnown() {
+		return ""
+	}
+	return id.AsString()
+}
@@ -0,0 +1,64 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package testing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform/internal/providers"
+)
+
+func TestFaultInjectorHealsAfterCount(t *testing.T) {
+	fi := NewFaultInjector()
+	fi.Inject(&Fault{
+		RPC:      "PlanResourceChange",
+		TypeName: "testing_resource",
+		Err:      errors.New("boom"),
+		Count:    2,
+	})
+
+	for i := 0; i < 2; i++ {
+		fault := fi.trigger("PlanResourceChange", "testing_resource", "")
+		if fault.Err == nil {
+			t.Fatalf("expected the fault to fire on attempt %d", i+1)
+		}
+	}
+
+	fault := fi.trigger("PlanResourceChange", "testing_resource", "")
+	if fault.Err != nil {
+		t.Fatalf("expected the fault to have healed after its Count was exhausted, got error: %s", fault.Err)
+	}
+}
+
+func TestFaultInjectorMatchesByResourceID(t *testing.T) {
+	fi := NewFaultInjector()
+	fi.Inject(&Fault{
+		RPC:        "ReadResource",
+		TypeName:   "testing_resource",
+		ResourceID: "targeted",
+		Err:        errors.New("boom"),
+	})
+
+	if fault := fi.trigger("ReadResource", "testing_resource", "other"); fault.Err != nil {
+		t.Fatalf("expected no fault for a non-matching resource id, got: %s", fault.Err)
+	}
+	if fault := fi.trigger("ReadResource", "testing_resource", "targeted"); fault.Err == nil {
+		t.Fatal("expected the fault to fire for its targeted resource id")
+	}
+}
+
+func TestFaultInjectorDeferred(t *testing.T) {
+	fi := NewFaultInjector()
+	deferred := &providers.Deferred{Reason: providers.DeferredReasonResourceConfigUnknown}
+	fi.Inject(&Fault{
+		RPC:      "PlanResourceChange",
+		Deferred: deferred,
+	})
+
+	fault := fi.trigger("PlanResourceChange", "testing_resource", "")
+	if fault.Deferred != deferred {
+		t.Fatal("expected trigger to return the injected Deferred response")
+	}
+}
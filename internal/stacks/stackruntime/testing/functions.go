@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package testing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/hashicorp/terraform/internal/providers"
+)
+
+// testingFunctions are the provider functions, "echo" among them, registered
+// in NewProviderWithData's GetProviderSchemaResponse. Each validates its
+// argument types via cty and returns a typed response, so tests can cover
+// function planning, unknown-argument propagation, and error surfaces
+// without needing a real provider.
+var testingFunctions = map[string]providers.FunctionDecl{
+	"echo": {
+		Parameters: []providers.FunctionParam{
+			{Name: "value", Type: cty.DynamicPseudoType},
+		},
+		ReturnType: cty.DynamicPseudoType,
+	},
+	"concat": {
+		Parameters: []providers.FunctionParam{
+			{Name: "values", Type: cty.List(cty.String)},
+		},
+		ReturnType: cty.String,
+	},
+	"sum": {
+		Parameters: []providers.FunctionParam{
+			{Name: "values", Type: cty.List(cty.Number)},
+		},
+		ReturnType: cty.Number,
+	},
+	"to_json": {
+		Parameters: []providers.FunctionParam{
+			{Name: "value", Type: cty.DynamicPseudoType},
+		},
+		ReturnType: cty.String,
+	},
+	"from_json": {
+		Parameters: []providers.FunctionParam{
+			{Name: "json", Type: cty.String},
+		},
+		ReturnType: cty.DynamicPseudoType,
+	},
+	"sleep": {
+		Parameters: []providers.FunctionParam{
+			{Name: "seconds", Type: cty.Number},
+		},
+		ReturnType: cty.Number,
+	},
+	"fail": {
+		Parameters: []providers.FunctionParam{
+			{Name: "message", Type: cty.String},
+		},
+		ReturnType: cty.DynamicPseudoType,
+	},
+}
+
+// callTestingFunction dispatches a CallFunctionRequest for one of
+// testingFunctions. It returns false if name isn't one it recognizes.
+func callTestingFunction(name string, args []cty.Value) (providers.CallFunctionResponse, bool) {
+	switch name {
+	case "echo":
+		return providers.CallFunctionResponse{Result: args[0]}, true
+	case "concat":
+		return callConcat(args[0]), true
+	case "sum":
+		return callSum(args[0]), true
+	case "to_json":
+		return callToJSON(args[0]), true
+	case "from_json":
+		return callFromJSON(args[0]), true
+	case "sleep":
+		return callSleep(args[0]), true
+	case "fail":
+		return callFail(args[0]), true
+	default:
+		return providers.CallFunctionResponse{}, false
+	}
+}
+
+func callConcat(list cty.Value) providers.CallFunctionResponse {
+	var b strings.Builder
+	i := 0
+	for it := list.ElementIterator(); it.Next(); i++ {
+		_, v := it.Element()
+		if v.IsNull() || !v.IsKnown() {
+			return providers.CallFunctionResponse{Err: fmt.Errorf("concat: element %d must not be null or unknown", i)}
+		}
+		b.WriteString(v.AsString())
+	}
+	return providers.CallFunctionResponse{Result: cty.StringVal(b.String())}
+}
+
+func callSum(list cty.Value) providers.CallFunctionResponse {
+	total := cty.Zero
+	i := 0
+	for it := list.ElementIterator(); it.Next(); i++ {
+		_, v := it.Element()
+		if v.IsNull() || !v.IsKnown() {
+			return providers.CallFunctionResponse{Err: fmt.Errorf("sum: element %d must not be null or unknown", i)}
+		}
+		total = total.Add(v)
+	}
+	return providers.CallFunctionResponse{Result: total}
+}
+
+func callToJSON(value cty.Value) providers.CallFunctionResponse {
+	raw, err := ctyjson.Marshal(value, value.Type())
+	if err != nil {
+		return providers.CallFunctionResponse{Err: err}
+	}
+	return providers.CallFunctionResponse{Result: cty.StringVal(string(raw))}
+}
+
+func callFromJSON(str cty.Value) providers.CallFunctionResponse {
+	buf := []byte(str.AsString())
+	ty, err := ctyjson.ImpliedType(buf)
+	if err != nil {
+		return providers.CallFunctionResponse{Err: err}
+	}
+	value, err := ctyjson.Unmarshal(buf, ty)
+	if err != nil {
+		return providers.CallFunctionResponse{Err: err}
+	}
+	return providers.CallFunctionResponse{Result: value}
+}
+
+func callSleep(seconds cty.Value) providers.CallFunctionResponse {
+	f, _ := seconds.AsBigFloat().Float64()
+	time.Sleep(time.Duration(f * float64(time.Second)))
+	return providers.CallFunctionResponse{Result: seconds}
+}
+
+func callFail(message cty.Value) providers.CallFunctionResponse {
+	return providers.CallFunctionResponse{Err: fmt.Errorf("%s", message.AsString())}
+}
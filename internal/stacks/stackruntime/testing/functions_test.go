@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package testing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestCallTestingFunctions(t *testing.T) {
+	resp, ok := callTestingFunction("concat", []cty.Value{
+		cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+	})
+	if !ok {
+		t.Fatal("expected concat to be recognized")
+	}
+	if resp.Err != nil {
+		t.Fatalf("unexpected error: %s", resp.Err)
+	}
+	if got := resp.Result.AsString(); got != "ab" {
+		t.Fatalf("wrong result\ngot:  %s\nwant: ab", got)
+	}
+
+	resp, ok = callTestingFunction("sum", []cty.Value{
+		cty.ListVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)}),
+	})
+	if !ok {
+		t.Fatal("expected sum to be recognized")
+	}
+	if resp.Err != nil {
+		t.Fatalf("unexpected error: %s", resp.Err)
+	}
+	if got, _ := resp.Result.AsBigFloat().Float64(); got != 3 {
+		t.Fatalf("wrong result\ngot:  %v\nwant: 3", got)
+	}
+
+	if _, ok := callTestingFunction("nonexistent", nil); ok {
+		t.Fatal("expected an unrecognized function name to report ok=false")
+	}
+}
+
+func TestCallConcatRejectsNullOrUnknownElement(t *testing.T) {
+	resp, ok := callTestingFunction("concat", []cty.Value{
+		cty.ListVal([]cty.Value{cty.StringVal("a"), cty.NullVal(cty.String)}),
+	})
+	if !ok {
+		t.Fatal("expected concat to be recognized")
+	}
+	if resp.Err == nil {
+		t.Fatal("expected an error for a null list element, got none")
+	}
+
+	resp, ok = callTestingFunction("concat", []cty.Value{
+		cty.ListVal([]cty.Value{cty.StringVal("a"), cty.UnknownVal(cty.String)}),
+	})
+	if !ok {
+		t.Fatal("expected concat to be recognized")
+	}
+	if resp.Err == nil {
+		t.Fatal("expected an error for an unknown list element, got none")
+	}
+}
+
+func TestCallSumRejectsNullOrUnknownElement(t *testing.T) {
+	resp, ok := callTestingFunction("sum", []cty.Value{
+		cty.ListVal([]cty.Value{cty.NumberIntVal(1), cty.NullVal(cty.Number)}),
+	})
+	if !ok {
+		t.Fatal("expected sum to be recognized")
+	}
+	if resp.Err == nil {
+		t.Fatal("expected an error for a null list element, got none")
+	}
+
+	resp, ok = callTestingFunction("sum", []cty.Value{
+		cty.ListVal([]cty.Value{cty.NumberIntVal(1), cty.UnknownVal(cty.Number)}),
+	})
+	if !ok {
+		t.Fatal("expected sum to be recognized")
+	}
+	if resp.Err == nil {
+		t.Fatal("expected an error for an unknown list element, got none")
+	}
+}
+
+func TestCallToAndFromJSONRoundTrip(t *testing.T) {
+	original := cty.ObjectVal(map[string]cty.Value{
+		"id":    cty.StringVal("abc"),
+		"count": cty.NumberIntVal(3),
+	})
+
+	toResp, ok := callTestingFunction("to_json", []cty.Value{original})
+	if !ok {
+		t.Fatal("expected to_json to be recognized")
+	}
+	if toResp.Err != nil {
+		t.Fatalf("unexpected error: %s", toResp.Err)
+	}
+
+	fromResp, ok := callTestingFunction("from_json", []cty.Value{toResp.Result})
+	if !ok {
+		t.Fatal("expected from_json to be recognized")
+	}
+	if fromResp.Err != nil {
+		t.Fatalf("unexpected error: %s", fromResp.Err)
+	}
+
+	got := fromResp.Result.GetAttr("id")
+	if got.AsString() != "abc" {
+		t.Fatalf("wrong id after round-trip\ngot:  %s\nwant: abc", got.AsString())
+	}
+	if count, _ := fromResp.Result.GetAttr("count").AsBigFloat().Float64(); count != 3 {
+		t.Fatalf("wrong count after round-trip\ngot:  %v\nwant: 3", count)
+	}
+}
+
+func TestCallFromJSONRejectsInvalidJSON(t *testing.T) {
+	resp, ok := callTestingFunction("from_json", []cty.Value{cty.StringVal("not valid json")})
+	if !ok {
+		t.Fatal("expected from_json to be recognized")
+	}
+	if resp.Err == nil {
+		t.Fatal("expected an error for invalid JSON input, got none")
+	}
+}
+
+func TestCallSleep(t *testing.T) {
+	start := time.Now()
+	resp, ok := callTestingFunction("sleep", []cty.Value{cty.NumberFloatVal(0.05)})
+	if !ok {
+		t.Fatal("expected sleep to be recognized")
+	}
+	if resp.Err != nil {
+		t.Fatalf("unexpected error: %s", resp.Err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected sleep to block for at least 50ms, only took %s", elapsed)
+	}
+	if got, _ := resp.Result.AsBigFloat().Float64(); got != 0.05 {
+		t.Fatalf("expected sleep to return its argument unchanged\ngot:  %v\nwant: 0.05", got)
+	}
+}
+
+func TestCallFail(t *testing.T) {
+	resp, ok := callTestingFunction("fail", []cty.Value{cty.StringVal("boom")})
+	if !ok {
+		t.Fatal("expected fail to be recognized")
+	}
+	if resp.Err == nil {
+		t.Fatal("expected fail to return an error")
+	}
+	if resp.Err.Error() != "boom" {
+		t.Fatalf("wrong error message\ngot:  %s\nwant: boom", resp.Err.Error())
+	}
+}
@@ -57,6 +57,13 @@ var (
 			"value": {Type: cty.String, Computed: true},
 		},
 	}
+
+	TestingEphemeralResourceSchema = &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"id":    {Type: cty.String, Optional: true, Computed: true},
+			"value": {Type: cty.String, Optional: true},
+		},
+	}
 )
 
 // MockProvider wraps the standard MockProvider with a simple in-memory
@@ -65,6 +72,11 @@ type MockProvider struct {
 	*testing_provider.MockProvider
 
 	ResourceStore *ResourceStore
+
+	// FaultInjector lets tests inject latency, transient errors, and
+	// deferred responses into this provider's RPCs. See FaultInjector for
+	// details.
+	FaultInjector *FaultInjector
 }
 
 // NewProvider returns a new MockProvider with an empty data store.
@@ -83,6 +95,8 @@ func NewProviderWithData(t *testing.T, store *ResourceStore) *MockProvider {
 	// in case it isn't being cleaned up properly
 	currentStackTrace := debug.Stack()
 
+	faultInjector := NewFaultInjector()
+
 	provider := &MockProvider{
 		MockProvider: &testing_provider.MockProvider{
 			GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
@@ -119,14 +133,12 @@ func NewProviderWithData(t *testing.T, store *ResourceStore) *MockProvider {
 						Block: TestingDataSourceSchema,
 					},
 				},
-				Functions: map[string]providers.FunctionDecl{
-					"echo": {
-						Parameters: []providers.FunctionParam{
-							{Name: "value", Type: cty.DynamicPseudoType},
-						},
-						ReturnType: cty.DynamicPseudoType,
+				EphemeralResourceTypes: map[string]providers.Schema{
+					"testing_ephemeral_resource": {
+						Block: TestingEphemeralResourceSchema,
 					},
 				},
+				Functions: testingFunctions,
 				ServerCapabilities: providers.ServerCapabilities{
 					MoveResourceState: true,
 				},
@@ -144,17 +156,39 @@ func NewProviderWithData(t *testing.T, store *ResourceStore) *MockProvider {
 				return providers.ConfigureProviderResponse{}
 			},
 			PlanResourceChangeFn: func(request providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+				if fault := faultInjector.trigger("PlanResourceChange", request.TypeName, resourceID(request.ProposedNewState)); fault.Err != nil || fault.Deferred != nil {
+					var diags tfdiags.Diagnostics
+					if fault.Err != nil {
+						diags = diags.Append(fault.Err)
+					}
+					return providers.PlanResourceChangeResponse{Diagnostics: diags, Deferred: fault.Deferred}
+				}
 				return getResource(request.TypeName).Plan(request, store)
 			},
 			ApplyResourceChangeFn: func(request providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+				if fault := faultInjector.trigger("ApplyResourceChange", request.TypeName, resourceID(request.PlannedState)); fault.Err != nil {
+					var diags tfdiags.Diagnostics
+					diags = diags.Append(fault.Err)
+					return providers.ApplyResourceChangeResponse{Diagnostics: diags}
+				}
 				return getResource(request.TypeName).Apply(request, store)
 			},
 			ReadResourceFn: func(request providers.ReadResourceRequest) providers.ReadResourceResponse {
+				if fault := faultInjector.trigger("ReadResource", request.TypeName, resourceID(request.PriorState)); fault.Err != nil {
+					var diags tfdiags.Diagnostics
+					diags = diags.Append(fault.Err)
+					return providers.ReadResourceResponse{Diagnostics: diags}
+				}
 				return getResource(request.TypeName).Read(request, store)
 			},
 			ReadDataSourceFn: func(request providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
 				var diags tfdiags.Diagnostics
 
+				if fault := faultInjector.trigger("ReadDataSource", request.TypeName, resourceID(request.Config)); fault.Err != nil {
+					diags = diags.Append(fault.Err)
+					return providers.ReadDataSourceResponse{Diagnostics: diags}
+				}
+
 				id := request.Config.GetAttr("id").AsString()
 				value, exists := store.Get(id)
 				if !exists {
@@ -167,6 +201,13 @@ func NewProviderWithData(t *testing.T, store *ResourceStore) *MockProvider {
 			},
 			ImportResourceStateFn: func(request providers.ImportResourceStateRequest) providers.ImportResourceStateResponse {
 				id := request.ID
+				if fault := faultInjector.trigger("ImportResourceState", request.TypeName, id); fault.Err != nil {
+					return providers.ImportResourceStateResponse{
+						Diagnostics: tfdiags.Diagnostics{
+							tfdiags.Sourceless(tfdiags.Error, "import failed", fault.Err.Error()),
+						},
+					}
+				}
 				value, exists := store.Get(id)
 				if !exists {
 					return providers.ImportResourceStateResponse{
@@ -185,6 +226,13 @@ func NewProviderWithData(t *testing.T, store *ResourceStore) *MockProvider {
 				}
 			},
 			MoveResourceStateFn: func(request providers.MoveResourceStateRequest) providers.MoveResourceStateResponse {
+				if fault := faultInjector.trigger("MoveResourceState", request.SourceTypeName, ""); fault.Err != nil {
+					return providers.MoveResourceStateResponse{
+						Diagnostics: tfdiags.Diagnostics{
+							tfdiags.Sourceless(tfdiags.Error, "move failed", fault.Err.Error()),
+						},
+					}
+				}
 				if request.SourceTypeName != "testing_resource" && request.TargetTypeName != "testing_deferred_resource" {
 					return providers.MoveResourceStateResponse{
 						Diagnostics: tfdiags.Diagnostics{
@@ -218,14 +266,67 @@ func NewProviderWithData(t *testing.T, store *ResourceStore) *MockProvider {
 					TargetState: target,
 				}
 			},
+			OpenEphemeralResourceFn: func(request providers.OpenEphemeralResourceRequest) providers.OpenEphemeralResourceResponse {
+				id := request.Config.GetAttr("id")
+				if id.IsNull() {
+					id = cty.StringVal(mustGenerateUUID())
+				}
+
+				fault := faultInjector.trigger("OpenEphemeralResource", "testing_ephemeral_resource", id.AsString())
+				if fault.Err != nil {
+					var diags tfdiags.Diagnostics
+					diags = diags.Append(fault.Err)
+					return providers.OpenEphemeralResourceResponse{Diagnostics: diags}
+				}
+
+				result := cty.ObjectVal(map[string]cty.Value{
+					"id":    id,
+					"value": request.Config.GetAttr("value"),
+				})
+				store.Set(id.AsString(), result)
+				return providers.OpenEphemeralResourceResponse{
+					Result:  result,
+					RenewAt: fault.RenewAt,
+				}
+			},
+			RenewEphemeralResourceFn: func(request providers.RenewEphemeralResourceRequest) providers.RenewEphemeralResourceResponse {
+				fault := faultInjector.trigger("RenewEphemeralResource", "testing_ephemeral_resource", "")
+				if fault.Err != nil {
+					var diags tfdiags.Diagnostics
+					diags = diags.Append(fault.Err)
+					return providers.RenewEphemeralResourceResponse{Diagnostics: diags}
+				}
+				// Nothing to renew on its own: testing_ephemeral_resource
+				// values never expire, so the private state is passed
+				// through unchanged and no further renewal is scheduled
+				// unless a FaultInjector fault sets RenewAt.
+				return providers.RenewEphemeralResourceResponse{
+					Private: request.Private,
+					RenewAt: fault.RenewAt,
+				}
+			},
+			CloseEphemeralResourceFn: func(request providers.CloseEphemeralResourceRequest) providers.CloseEphemeralResourceResponse {
+				fault := faultInjector.trigger("CloseEphemeralResource", "testing_ephemeral_resource", "")
+				if fault.Err != nil {
+					var diags tfdiags.Diagnostics
+					diags = diags.Append(fault.Err)
+					return providers.CloseEphemeralResourceResponse{Diagnostics: diags}
+				}
+				return providers.CloseEphemeralResourceResponse{}
+			},
 			CallFunctionFn: func(request providers.CallFunctionRequest) providers.CallFunctionResponse {
-				// Just echo the first argument back as the result.
-				return providers.CallFunctionResponse{
-					Result: request.Arguments[0],
+				if fault := faultInjector.trigger("CallFunction", request.FunctionName, ""); fault.Err != nil {
+					return providers.CallFunctionResponse{Err: fault.Err}
+				}
+				response, ok := callTestingFunction(request.FunctionName, request.Arguments)
+				if !ok {
+					return providers.CallFunctionResponse{Err: fmt.Errorf("unknown function %q", request.FunctionName)}
 				}
+				return response
 			},
 		},
 		ResourceStore: store,
+		FaultInjector: faultInjector,
 	}
 
 	t.Cleanup(func() {
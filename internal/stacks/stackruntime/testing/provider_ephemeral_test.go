@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package testing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/terraform/internal/providers"
+)
+
+func TestMockProviderEphemeralResourceLifecycle(t *testing.T) {
+	provider := NewProvider(t)
+	defer provider.Close()
+
+	openResp := provider.OpenEphemeralResourceFn(providers.OpenEphemeralResourceRequest{
+		TypeName: "testing_ephemeral_resource",
+		Config: cty.ObjectVal(map[string]cty.Value{
+			"id":    cty.NullVal(cty.String),
+			"value": cty.StringVal("hello"),
+		}),
+	})
+	if openResp.Diagnostics.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", openResp.Diagnostics.Err())
+	}
+
+	id := openResp.Result.GetAttr("id")
+	if id.IsNull() {
+		t.Fatal("expected Open to assign an id")
+	}
+
+	stored, exists := provider.ResourceStore.Get(id.AsString())
+	if !exists {
+		t.Fatal("expected Open to record the resource in the store")
+	}
+	if got := stored.GetAttr("value").AsString(); got != "hello" {
+		t.Fatalf("wrong stored value\ngot:  %s\nwant: hello", got)
+	}
+
+	renewResp := provider.RenewEphemeralResourceFn(providers.RenewEphemeralResourceRequest{
+		TypeName: "testing_ephemeral_resource",
+		Private:  []byte("private-state"),
+	})
+	if renewResp.Diagnostics.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", renewResp.Diagnostics.Err())
+	}
+	if string(renewResp.Private) != "private-state" {
+		t.Fatalf("expected Renew to pass private state through unchanged, got %q", renewResp.Private)
+	}
+
+	closeResp := provider.CloseEphemeralResourceFn(providers.CloseEphemeralResourceRequest{
+		TypeName: "testing_ephemeral_resource",
+	})
+	if closeResp.Diagnostics.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", closeResp.Diagnostics.Err())
+	}
+}
+
+func TestMockProviderEphemeralResourceScheduledRenewal(t *testing.T) {
+	provider := NewProvider(t)
+	defer provider.Close()
+
+	renewAt := time.Now().Add(time.Minute)
+	provider.FaultInjector.Inject(&Fault{
+		RPC:     "OpenEphemeralResource",
+		RenewAt: renewAt,
+	})
+
+	openResp := provider.OpenEphemeralResourceFn(providers.OpenEphemeralResourceRequest{
+		TypeName: "testing_ephemeral_resource",
+		Config: cty.ObjectVal(map[string]cty.Value{
+			"id":    cty.NullVal(cty.String),
+			"value": cty.StringVal("hello"),
+		}),
+	})
+	if openResp.Diagnostics.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", openResp.Diagnostics.Err())
+	}
+	if !openResp.RenewAt.Equal(renewAt) {
+		t.Fatalf("wrong RenewAt\ngot:  %s\nwant: %s", openResp.RenewAt, renewAt)
+	}
+
+	provider.FaultInjector.Inject(&Fault{
+		RPC:     "RenewEphemeralResource",
+		RenewAt: renewAt.Add(time.Minute),
+	})
+	renewResp := provider.RenewEphemeralResourceFn(providers.RenewEphemeralResourceRequest{
+		TypeName: "testing_ephemeral_resource",
+	})
+	if renewResp.Diagnostics.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", renewResp.Diagnostics.Err())
+	}
+	if !renewResp.RenewAt.Equal(renewAt.Add(time.Minute)) {
+		t.Fatalf("wrong RenewAt\ngot:  %s\nwant: %s", renewResp.RenewAt, renewAt.Add(time.Minute))
+	}
+
+	provider.CloseEphemeralResourceFn(providers.CloseEphemeralResourceRequest{
+		TypeName: "testing_ephemeral_resource",
+	})
+}
+
+func TestMockProviderEphemeralResourceCloseTimeError(t *testing.T) {
+	provider := NewProvider(t)
+	defer provider.Close()
+
+	openResp := provider.OpenEphemeralResourceFn(providers.OpenEphemeralResourceRequest{
+		TypeName: "testing_ephemeral_resource",
+		Config: cty.ObjectVal(map[string]cty.Value{
+			"id":    cty.NullVal(cty.String),
+			"value": cty.StringVal("hello"),
+		}),
+	})
+	if openResp.Diagnostics.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", openResp.Diagnostics.Err())
+	}
+
+	provider.FaultInjector.Inject(&Fault{
+		RPC: "CloseEphemeralResource",
+		Err: errors.New("close failed"),
+	})
+
+	closeResp := provider.CloseEphemeralResourceFn(providers.CloseEphemeralResourceRequest{
+		TypeName: "testing_ephemeral_resource",
+	})
+	if !closeResp.Diagnostics.HasErrors() {
+		t.Fatal("expected Close to report the injected fault as a diagnostic")
+	}
+}
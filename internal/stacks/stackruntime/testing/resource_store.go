@@ -0,0 +1,262 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// ResourceStore is a simple in-memory, concurrency-safe store of resource
+// values keyed by id, shared by the mock resource, data source, and
+// ephemeral resource implementations in this package.
+type ResourceStore struct {
+	mu   sync.Mutex
+	data map[string]cty.Value
+
+	journal JournalFile
+}
+
+// JournalFile is the subset of *os.File that WithJournal and Compact need
+// in order to append new records, replay and repair existing ones, and
+// rewrite the file in place. *os.File satisfies this directly; an in-memory
+// implementation needs to support Seek and Truncate the same way a real
+// file does.
+type JournalFile interface {
+	io.Reader
+	io.Writer
+	io.Seeker
+	Truncate(size int64) error
+}
+
+// NewResourceStore returns an empty ResourceStore.
+func NewResourceStore() *ResourceStore {
+	return &ResourceStore{
+		data: make(map[string]cty.Value),
+	}
+}
+
+// Get returns the value stored under id, if any.
+func (s *ResourceStore) Get(id string) (cty.Value, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[id]
+	return v, ok
+}
+
+// Set stores value under id, overwriting any previous value.
+func (s *ResourceStore) Set(id string, value cty.Value) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setLocked(id, value)
+}
+
+// Delete removes the value stored under id, if any.
+func (s *ResourceStore) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleteLocked(id)
+}
+
+// WithJournal enables write-ahead journaling on the store: every subsequent
+// Set and Delete is appended to j as a newline-delimited JSON operation.
+// This lets multiple `terraform` invocations in an integration test share a
+// store by opening the same file and reproduce a flaky plan/apply sequence
+// by replaying it.
+//
+// Before enabling the journal, WithJournal replays any operations already
+// present in j, so a caller can reopen a journal file left over from a
+// previous process and resume from where it left off. Replay deduplicates
+// by id: only the last operation recorded for a given id takes effect. If
+// j's final record is truncated (for example because a previous process was
+// killed mid-write), WithJournal repairs the file in place — it truncates j
+// to the end of the last complete record before resuming journaling there,
+// so the discarded bytes are never left sitting in the middle of the file
+// for a later Set or Delete to be appended after.
+func (s *ResourceStore) WithJournal(j JournalFile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.replayAndRepairLocked(j); err != nil {
+		return err
+	}
+	s.journal = j
+	return nil
+}
+
+// journalOp is the on-disk representation of a single Set or Delete applied
+// to a ResourceStore. Each op is written as its own line by appendLocked;
+// replayAndRepairLocked relies on that newline framing to tell a genuinely
+// truncated trailing record (no terminating newline, or invalid JSON within
+// one) from the rest of the file, which it trusts completely.
+type journalOp struct {
+	Op       string                             `json:"op"` // "set", "delete", or "snapshot"
+	ID       string                             `json:"id,omitempty"`
+	Value    *ctyjson.SimpleJSONValue           `json:"value,omitempty"`
+	Snapshot map[string]ctyjson.SimpleJSONValue `json:"snapshot,omitempty"`
+}
+
+// replayAndRepairLocked replays every complete record in j, applying it to
+// the store, then truncates j to drop any trailing partial record and seeks
+// it to the end of the now-complete content so that subsequent appends
+// don't follow the discarded bytes.
+func (s *ResourceStore) replayAndRepairLocked(j JournalFile) error {
+	if _, err := j.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seeking resource store journal: %w", err)
+	}
+	content, err := io.ReadAll(j)
+	if err != nil {
+		return fmt.Errorf("reading resource store journal: %w", err)
+	}
+
+	validLen := 0
+	for {
+		rest := content[validLen:]
+		if len(rest) == 0 {
+			break
+		}
+		nl := bytes.IndexByte(rest, '\n')
+		if nl == -1 {
+			// No terminating newline: a record was still being written
+			// when whatever wrote it stopped. Discard it.
+			break
+		}
+		line := rest[:nl]
+		if len(bytes.TrimSpace(line)) == 0 {
+			validLen += nl + 1
+			continue
+		}
+
+		var op journalOp
+		if err := json.Unmarshal(line, &op); err != nil {
+			// A complete line that isn't valid JSON means the same thing as
+			// a missing trailing newline: the record it was part of never
+			// finished writing. Discard it and everything after it, rather
+			// than reporting an error, which would make every journal with
+			// a crash in its history permanently unreadable.
+			break
+		}
+		switch op.Op {
+		case "set":
+			if op.Value == nil {
+				return fmt.Errorf("replaying resource store journal: %q op for %q is missing a value", op.Op, op.ID)
+			}
+			s.setLocked(op.ID, op.Value.Value)
+		case "delete":
+			s.deleteLocked(op.ID)
+		case "snapshot":
+			// Written by Compact: replaces everything replayed so far with
+			// the state as of the snapshot, so that the journal that
+			// follows it only has to account for ops since then.
+			data := make(map[string]cty.Value, len(op.Snapshot))
+			for id, value := range op.Snapshot {
+				data[id] = value.Value
+			}
+			s.data = data
+		default:
+			return fmt.Errorf("replaying resource store journal: unrecognized op %q", op.Op)
+		}
+		validLen += nl + 1
+	}
+
+	if validLen < len(content) {
+		if err := j.Truncate(int64(validLen)); err != nil {
+			return fmt.Errorf("repairing truncated resource store journal: %w", err)
+		}
+	}
+	if _, err := j.Seek(int64(validLen), io.SeekStart); err != nil {
+		return fmt.Errorf("seeking resource store journal: %w", err)
+	}
+	return nil
+}
+
+func (s *ResourceStore) setLocked(id string, value cty.Value) {
+	s.data[id] = value
+	s.appendLocked(journalOp{Op: "set", ID: id, Value: &ctyjson.SimpleJSONValue{Value: value}})
+}
+
+func (s *ResourceStore) deleteLocked(id string) {
+	delete(s.data, id)
+	s.appendLocked(journalOp{Op: "delete", ID: id})
+}
+
+func (s *ResourceStore) appendLocked(op journalOp) {
+	if s.journal == nil {
+		return
+	}
+	if err := json.NewEncoder(s.journal).Encode(op); err != nil {
+		// The journal is best-effort: a write failure shouldn't make the
+		// store itself unusable, but the journal can no longer be trusted
+		// to reflect subsequent state, so stop writing to it.
+		s.journal = nil
+	}
+}
+
+// Snapshot writes the store's entire contents to w as a single JSON object
+// mapping id to value.
+func (s *ResourceStore) Snapshot(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshotLocked(w)
+}
+
+func (s *ResourceStore) snapshotLocked(w io.Writer) error {
+	raw := make(map[string]ctyjson.SimpleJSONValue, len(s.data))
+	for id, value := range s.data {
+		raw[id] = ctyjson.SimpleJSONValue{Value: value}
+	}
+	return json.NewEncoder(w).Encode(raw)
+}
+
+// Restore replaces the store's contents with the snapshot encoded in r, as
+// produced by Snapshot.
+func (s *ResourceStore) Restore(r io.Reader) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var raw map[string]ctyjson.SimpleJSONValue
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return fmt.Errorf("restoring resource store snapshot: %w", err)
+	}
+	data := make(map[string]cty.Value, len(raw))
+	for id, value := range raw {
+		data[id] = value.Value
+	}
+	s.data = data
+	return nil
+}
+
+// Compact replaces j's contents with a single "snapshot" record of the
+// store's current state and continues journaling against j from there, so
+// that a long-lived journal (for example one shared across many
+// `terraform` invocations in an integration test) doesn't grow without
+// bound. The snapshot record is replayed by WithJournal the same way a
+// "set" or "delete" is, so a compacted journal reopens just like any other.
+func (s *ResourceStore) Compact(j JournalFile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	raw := make(map[string]ctyjson.SimpleJSONValue, len(s.data))
+	for id, value := range s.data {
+		raw[id] = ctyjson.SimpleJSONValue{Value: value}
+	}
+
+	if err := j.Truncate(0); err != nil {
+		return fmt.Errorf("compacting resource store: %w", err)
+	}
+	if _, err := j.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("compacting resource store: %w", err)
+	}
+	if err := json.NewEncoder(j).Encode(journalOp{Op: "snapshot", Snapshot: raw}); err != nil {
+		return fmt.Errorf("compacting resource store: %w", err)
+	}
+	s.journal = j
+	return nil
+}
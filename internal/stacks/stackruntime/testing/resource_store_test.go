@@ -0,0 +1,131 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package testing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestResourceStoreJournalRecoversFromTruncation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewResourceStore()
+	if err := store.WithJournal(f); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	store.Set("a", cty.StringVal("1"))
+	store.Set("b", cty.StringVal("2"))
+	store.Set("c", cty.StringVal("3"))
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash mid-write by cutting the tail off the last record.
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-5); err != nil {
+		t.Fatal(err)
+	}
+
+	f2, err := os.OpenFile(path, os.O_RDWR, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store2 := NewResourceStore()
+	if err := store2.WithJournal(f2); err != nil {
+		t.Fatalf("unexpected error recovering a truncated journal: %s", err)
+	}
+	if _, exists := store2.Get("c"); exists {
+		t.Fatal("expected the truncated record for \"c\" to be discarded")
+	}
+	if v, exists := store2.Get("a"); !exists || v.AsString() != "1" {
+		t.Fatalf("expected \"a\" to survive recovery, got %#v (exists=%v)", v, exists)
+	}
+
+	// The repaired journal must be positioned so that new records land
+	// after the recovered content, not after the discarded garbage.
+	store2.Set("d", cty.StringVal("4"))
+	if err := f2.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f3, err := os.OpenFile(path, os.O_RDWR, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f3.Close()
+	store3 := NewResourceStore()
+	if err := store3.WithJournal(f3); err != nil {
+		t.Fatalf("unexpected error on second reopen: %s", err)
+	}
+	if v, exists := store3.Get("d"); !exists || v.AsString() != "4" {
+		t.Fatalf("expected \"d\" written after recovery to survive a later reopen, got %#v (exists=%v)", v, exists)
+	}
+	if v, exists := store3.Get("a"); !exists || v.AsString() != "1" {
+		t.Fatalf("expected \"a\" to still be present, got %#v (exists=%v)", v, exists)
+	}
+}
+
+func TestResourceStoreCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	store := NewResourceStore()
+	if err := store.WithJournal(f); err != nil {
+		t.Fatal(err)
+	}
+	store.Set("a", cty.StringVal("1"))
+	store.Delete("a")
+	store.Set("a", cty.StringVal("2"))
+
+	before, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Compact(f); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	after, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Size() >= before.Size() {
+		t.Fatalf("expected Compact to shrink the journal file: was %d bytes, still %d bytes", before.Size(), after.Size())
+	}
+
+	store.Set("b", cty.StringVal("3"))
+
+	f2, err := os.OpenFile(path, os.O_RDWR, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f2.Close()
+	reopened := NewResourceStore()
+	if err := reopened.WithJournal(f2); err != nil {
+		t.Fatal(err)
+	}
+	if v, exists := reopened.Get("a"); !exists || v.AsString() != "2" {
+		t.Fatalf("expected \"a\" = 2 after compacting, got %#v (exists=%v)", v, exists)
+	}
+	if v, exists := reopened.Get("b"); !exists || v.AsString() != "3" {
+		t.Fatalf("expected \"b\" = 3 appended after compacting, got %#v (exists=%v)", v, exists)
+	}
+}